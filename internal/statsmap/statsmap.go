@@ -0,0 +1,167 @@
+// Package statsmap implements a purpose-built hash table for aggregating
+// per-station weather statistics directly against the mmapped measurements
+// file, without allocating a string per row.
+//
+// Keys are byte ranges [keyOffset, keyOffset+keyLen) into a shared backing
+// slice (typically an mmapped file). Lookups compare bytes directly against
+// that slice, so no string is ever allocated on the hot path; a string is
+// only materialized when results are read out via Results.
+package statsmap
+
+import "bytes"
+
+// entry holds the running aggregate for one station. Temperatures are
+// fixed-point integers (value * 10), matching the convention used by the
+// caller's parser.
+type entry struct {
+	keyOffset int32
+	keyLen    int32
+	min       int32
+	max       int32
+	sum       int64
+	count     int32
+}
+
+// Result is a materialized view of one station's aggregate, produced once
+// at output time.
+type Result struct {
+	Name  string
+	Min   int32
+	Max   int32
+	Sum   int64
+	Count int32
+}
+
+const (
+	initialCapacity = 1024
+	maxLoadFactor   = 0.7
+)
+
+// Table is an open-addressing hash table keyed by byte slices taken from a
+// shared backing slice. It uses linear probing and a power-of-two capacity.
+type Table struct {
+	data    []byte
+	slots   []int32 // 1-based index into entries; 0 means empty
+	entries []entry
+	mask    uint32
+}
+
+// New creates an empty Table whose keys reference offsets into data.
+func New(data []byte) *Table {
+	return &Table{
+		data:  data,
+		slots: make([]int32, initialCapacity),
+		mask:  initialCapacity - 1,
+	}
+}
+
+// fnv1a computes the 32-bit FNV-1a hash of b.
+func fnv1a(b []byte) uint32 {
+	const offset32 = 2166136261
+	const prime32 = 16777619
+	h := uint32(offset32)
+	for _, c := range b {
+		h ^= uint32(c)
+		h *= prime32
+	}
+	return h
+}
+
+// Add records one observation for the station named by data[keyStart:keyEnd].
+func (t *Table) Add(keyStart, keyEnd int, temp int32) {
+	t.upsert(keyStart, keyEnd, temp, temp, int64(temp), 1)
+}
+
+// upsert merges (min, max, sum, count) into the entry for the station named
+// by t.data[keyStart:keyEnd], inserting a new entry if it hasn't been seen
+// before.
+func (t *Table) upsert(keyStart, keyEnd int, min, max int32, sum int64, count int32) {
+	key := t.data[keyStart:keyEnd]
+	slot := fnv1a(key) & t.mask
+	for {
+		idx := t.slots[slot]
+		if idx == 0 {
+			t.entries = append(t.entries, entry{
+				keyOffset: int32(keyStart),
+				keyLen:    int32(keyEnd - keyStart),
+				min:       min,
+				max:       max,
+				sum:       sum,
+				count:     count,
+			})
+			t.slots[slot] = int32(len(t.entries))
+			if float64(len(t.entries)) > float64(len(t.slots))*maxLoadFactor {
+				t.grow()
+			}
+			return
+		}
+
+		e := &t.entries[idx-1]
+		if bytes.Equal(t.data[e.keyOffset:e.keyOffset+e.keyLen], key) {
+			if min < e.min {
+				e.min = min
+			}
+			if max > e.max {
+				e.max = max
+			}
+			e.sum += sum
+			e.count += count
+			return
+		}
+
+		slot = (slot + 1) & t.mask
+	}
+}
+
+// grow doubles the slot array and rehashes existing entries into it.
+func (t *Table) grow() {
+	newCap := len(t.slots) * 2
+	newSlots := make([]int32, newCap)
+	mask := uint32(newCap - 1)
+
+	for _, idx := range t.slots {
+		if idx == 0 {
+			continue
+		}
+		e := &t.entries[idx-1]
+		h := fnv1a(t.data[e.keyOffset : e.keyOffset+e.keyLen])
+		slot := h & mask
+		for newSlots[slot] != 0 {
+			slot = (slot + 1) & mask
+		}
+		newSlots[slot] = idx
+	}
+
+	t.slots = newSlots
+	t.mask = mask
+}
+
+// Merge folds other's entries into t. other and t must share the same
+// backing data slice.
+func (t *Table) Merge(other *Table) {
+	for _, e := range other.entries {
+		t.upsert(int(e.keyOffset), int(e.keyOffset+e.keyLen), e.min, e.max, e.sum, e.count)
+	}
+}
+
+// Len returns the number of distinct stations recorded.
+func (t *Table) Len() int {
+	return len(t.entries)
+}
+
+// Results materializes every station's aggregate, allocating a string per
+// station name. This is the only point at which station names are copied
+// out of the backing slice.
+func (t *Table) Results() []Result {
+	out := make([]Result, len(t.entries))
+	for i, e := range t.entries {
+		out[i] = Result{
+			Name:  string(t.data[e.keyOffset : e.keyOffset+e.keyLen]),
+			Min:   e.min,
+			Max:   e.max,
+			Sum:   e.sum,
+			Count: e.count,
+		}
+	}
+	return out
+}
@@ -0,0 +1,87 @@
+package statsmap
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestAddAndResults(t *testing.T) {
+	data := []byte("Abha;5.0\nAbha;27.4\nBerlin;12.3\n")
+	table := New(data)
+
+	table.Add(0, 4, 50)
+	table.Add(9, 13, 274)
+	table.Add(19, 25, 123)
+
+	results := table.Results()
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+
+	byName := make(map[string]Result, len(results))
+	for _, r := range results {
+		byName[r.Name] = r
+	}
+
+	abha, ok := byName["Abha"]
+	if !ok {
+		t.Fatalf("missing Abha entry")
+	}
+	if abha.Min != 50 || abha.Max != 274 || abha.Sum != 324 || abha.Count != 2 {
+		t.Fatalf("Abha = %+v, want min=50 max=274 sum=324 count=2", abha)
+	}
+
+	berlin, ok := byName["Berlin"]
+	if !ok {
+		t.Fatalf("missing Berlin entry")
+	}
+	if berlin.Min != 123 || berlin.Max != 123 || berlin.Sum != 123 || berlin.Count != 1 {
+		t.Fatalf("Berlin = %+v, want min=max=sum=123 count=1", berlin)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	data := []byte("Abha;5.0\nAbha;27.4\n")
+	a := New(data)
+	a.Add(0, 4, 50)
+
+	b := New(data)
+	b.Add(9, 13, 274)
+
+	a.Merge(b)
+
+	results := a.Results()
+	if len(results) != 1 {
+		t.Fatalf("got %d results, want 1", len(results))
+	}
+	if results[0].Min != 50 || results[0].Max != 274 || results[0].Sum != 324 || results[0].Count != 2 {
+		t.Fatalf("merged = %+v, want min=50 max=274 sum=324 count=2", results[0])
+	}
+}
+
+func TestGrowPreservesEntries(t *testing.T) {
+	const n = 5000
+	data := make([]byte, 0, n*8)
+	offsets := make([][2]int, 0, n)
+
+	for i := 0; i < n; i++ {
+		start := len(data)
+		name := []byte(fmt.Sprintf("S%04d", i))
+		data = append(data, name...)
+		offsets = append(offsets, [2]int{start, len(data)})
+	}
+
+	table := New(data)
+	for i, off := range offsets {
+		table.Add(off[0], off[1], int32(i))
+	}
+
+	if table.Len() != n {
+		t.Fatalf("got %d entries after growth, want %d", table.Len(), n)
+	}
+	for _, r := range table.Results() {
+		if r.Count != 1 {
+			t.Fatalf("entry %q has count %d, want 1", r.Name, r.Count)
+		}
+	}
+}
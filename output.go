@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/TimAnthonyAlexander/1brc-go/pkg/onebrc"
+)
+
+// Supported -format values.
+const (
+	formatClassic = "classic"
+	formatOneLine = "one-line"
+	formatJSON    = "json"
+	formatCSV     = "csv"
+)
+
+// stationResult pairs a station name back up with its Stats for output,
+// since onebrc.Table only carries the name as a map key.
+type stationResult struct {
+	Name string
+	onebrc.Stats
+}
+
+// sortedResults returns results in station-name order.
+func sortedResults(table onebrc.Table) []stationResult {
+	out := make([]stationResult, 0, len(table))
+	for name, s := range table {
+		out = append(out, stationResult{Name: name, Stats: s})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// mean computes the fixed-point mean of s, rounded to the nearest tenth –
+// the (sum + count/2)/count rounding shared by every output format.
+func mean(s stationResult) int64 {
+	return (s.Sum + int64(s.Count)/2) / int64(s.Count)
+}
+
+// fixedPointString renders a fixed-point value (real value * 10) as "X.Y",
+// handling the -1.0 < v < 0 range explicitly since v/10 truncates to 0
+// there and would otherwise drop the sign.
+func fixedPointString(v int64) string {
+	sign := ""
+	if v < 0 {
+		sign = "-"
+		v = -v
+	}
+	return fmt.Sprintf("%s%d.%d", sign, v/10, v%10)
+}
+
+// writeResults renders results in the given format to w.
+func writeResults(w io.Writer, format string, results []stationResult) error {
+	switch format {
+	case formatClassic:
+		return writeClassic(w, results)
+	case formatOneLine:
+		return writeOneLine(w, results)
+	case formatJSON:
+		return writeJSON(w, results)
+	case formatCSV:
+		return writeCSV(w, results)
+	default:
+		return fmt.Errorf("unknown format %q (want classic, one-line, json, or csv)", format)
+	}
+}
+
+// writeClassic renders "station;min/mean/max" lines, one per station.
+func writeClassic(w io.Writer, results []stationResult) error {
+	for _, s := range results {
+		if _, err := fmt.Fprintf(w, "%s;%s/%s/%s\n", s.Name,
+			fixedPointString(int64(s.Min)), fixedPointString(mean(s)), fixedPointString(int64(s.Max))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeOneLine renders the canonical 1BRC format:
+// {Abha=5.0/18.0/27.4, Abidjan=15.7/26.0/34.1, ...}
+func writeOneLine(w io.Writer, results []stationResult) error {
+	if _, err := io.WriteString(w, "{"); err != nil {
+		return err
+	}
+	for i, s := range results {
+		if i > 0 {
+			if _, err := io.WriteString(w, ", "); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "%s=%s/%s/%s", s.Name,
+			fixedPointString(int64(s.Min)), fixedPointString(mean(s)), fixedPointString(int64(s.Max))); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "}\n")
+	return err
+}
+
+// writeJSON renders {"Abha":{"min":5.0,"mean":18.0,"max":27.4}, ...}.
+func writeJSON(w io.Writer, results []stationResult) error {
+	if _, err := io.WriteString(w, "{"); err != nil {
+		return err
+	}
+	for i, s := range results {
+		if i > 0 {
+			if _, err := io.WriteString(w, ", "); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "%q:{\"min\":%s,\"mean\":%s,\"max\":%s}", s.Name,
+			fixedPointString(int64(s.Min)), fixedPointString(mean(s)), fixedPointString(int64(s.Max))); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "}\n")
+	return err
+}
+
+// writeCSV renders a "station,min,mean,max" header followed by one row per
+// station.
+func writeCSV(w io.Writer, results []stationResult) error {
+	if _, err := io.WriteString(w, "station,min,mean,max\n"); err != nil {
+		return err
+	}
+	for _, s := range results {
+		if _, err := fmt.Fprintf(w, "%s,%s,%s,%s\n", s.Name,
+			fixedPointString(int64(s.Min)), fixedPointString(mean(s)), fixedPointString(int64(s.Max))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
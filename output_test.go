@@ -0,0 +1,67 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/TimAnthonyAlexander/1brc-go/pkg/onebrc"
+)
+
+func sampleResults() []stationResult {
+	return []stationResult{
+		{Name: "Abha", Stats: onebrc.Stats{Min: 50, Max: 274, Sum: 324, Count: 2}},
+		{Name: "Berlin", Stats: onebrc.Stats{Min: 123, Max: 123, Sum: 123, Count: 1}},
+		// Min/mean/max all sit in (-1.0, 0.0), the range where v/10
+		// truncates to 0 and can silently drop the sign.
+		{Name: "Oslo", Stats: onebrc.Stats{Min: -5, Max: -5, Sum: -5, Count: 1}},
+	}
+}
+
+func TestWriteResultsFormats(t *testing.T) {
+	cases := []struct {
+		format string
+		want   string
+	}{
+		{formatClassic, "Abha;5.0/16.2/27.4\nBerlin;12.3/12.3/12.3\nOslo;-0.5/-0.5/-0.5\n"},
+		{formatOneLine, "{Abha=5.0/16.2/27.4, Berlin=12.3/12.3/12.3, Oslo=-0.5/-0.5/-0.5}\n"},
+		{formatJSON, `{"Abha":{"min":5.0,"mean":16.2,"max":27.4}, "Berlin":{"min":12.3,"mean":12.3,"max":12.3}, "Oslo":{"min":-0.5,"mean":-0.5,"max":-0.5}}` + "\n"},
+		{formatCSV, "station,min,mean,max\nAbha,5.0,16.2,27.4\nBerlin,12.3,12.3,12.3\nOslo,-0.5,-0.5,-0.5\n"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.format, func(t *testing.T) {
+			var buf strings.Builder
+			if err := writeResults(&buf, tc.format, sampleResults()); err != nil {
+				t.Fatalf("writeResults(%q): %v", tc.format, err)
+			}
+			if buf.String() != tc.want {
+				t.Fatalf("writeResults(%q) = %q, want %q", tc.format, buf.String(), tc.want)
+			}
+		})
+	}
+}
+
+func TestFixedPointStringNegativeFraction(t *testing.T) {
+	cases := []struct {
+		v    int64
+		want string
+	}{
+		{-5, "-0.5"},
+		{-10, "-1.0"},
+		{-1, "-0.1"},
+		{0, "0.0"},
+		{5, "0.5"},
+	}
+	for _, tc := range cases {
+		if got := fixedPointString(tc.v); got != tc.want {
+			t.Fatalf("fixedPointString(%d) = %q, want %q", tc.v, got, tc.want)
+		}
+	}
+}
+
+func TestWriteResultsUnknownFormat(t *testing.T) {
+	var buf strings.Builder
+	if err := writeResults(&buf, "bogus", sampleResults()); err == nil {
+		t.Fatalf("expected an error for an unknown format")
+	}
+}
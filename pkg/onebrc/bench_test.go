@@ -0,0 +1,119 @@
+package onebrc
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+// legacyStationStats and legacyProcessChunk reproduce the pre-statsmap
+// implementation (map[string]stationStats, string-keyed) so the benchmarks
+// below can quantify the win from switching to statsmap.
+type legacyStationStats struct {
+	min   int32
+	max   int32
+	sum   int64
+	count int32
+}
+
+func legacyProcessChunk(data []byte, start, end int) map[string]legacyStationStats {
+	if start != 0 {
+		for start < end && data[start-1] != '\n' {
+			start++
+		}
+	}
+
+	local := make(map[string]legacyStationStats)
+
+	i := start
+	for i < end {
+		j := -1
+		for k := i; k < end; k++ {
+			if data[k] == '\n' {
+				j = k - i
+				break
+			}
+		}
+		if j == -1 {
+			break
+		}
+		line := data[i : i+j]
+		i += j + 1
+
+		if len(line) == 0 {
+			continue
+		}
+
+		semicolonIdx := -1
+		for k := len(line) - 1; k >= 0; k-- {
+			if line[k] == ';' {
+				semicolonIdx = k
+				break
+			}
+		}
+		if semicolonIdx <= 0 || semicolonIdx >= len(line)-1 {
+			continue
+		}
+
+		temp, ok := parseTemperatureFromBytes(line, semicolonIdx+1, len(line))
+		if !ok {
+			continue
+		}
+
+		station := string(line[:semicolonIdx])
+		stats := local[station]
+		if stats.count == 0 {
+			stats.min, stats.max = temp, temp
+		} else {
+			if temp < stats.min {
+				stats.min = temp
+			}
+			if temp > stats.max {
+				stats.max = temp
+			}
+		}
+		stats.sum += int64(temp)
+		stats.count++
+		local[station] = stats
+	}
+
+	return local
+}
+
+// genMeasurements builds a synthetic measurements buffer with a fixed
+// universe of station names, matching the shape of the real 1BRC dataset.
+func genMeasurements(lines, stations int) []byte {
+	r := rand.New(rand.NewSource(1))
+	names := make([]string, stations)
+	for i := range names {
+		names[i] = fmt.Sprintf("Station_%04d", i)
+	}
+
+	var buf []byte
+	for i := 0; i < lines; i++ {
+		name := names[r.Intn(stations)]
+		temp := r.Intn(1000) - 500
+		buf = append(buf, name...)
+		buf = append(buf, ';')
+		buf = append(buf, fmt.Sprintf("%d.%d\n", temp/10, abs32(int32(temp%10)))...)
+	}
+	return buf
+}
+
+func BenchmarkProcessChunkMap(b *testing.B) {
+	data := genMeasurements(200_000, 400)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		legacyProcessChunk(data, 0, len(data))
+	}
+}
+
+func BenchmarkProcessChunkStatsMap(b *testing.B) {
+	data := genMeasurements(200_000, 400)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		processChunk(data, 0, len(data))
+	}
+}
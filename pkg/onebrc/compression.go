@@ -0,0 +1,70 @@
+package onebrc
+
+import (
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// compressionKind identifies how a measurements file is encoded.
+type compressionKind int
+
+const (
+	compressionNone compressionKind = iota
+	compressionGzip
+	compressionBzip2
+	compressionZstd
+)
+
+// detectCompression determines the compression format of a file from its
+// extension, falling back to sniffing the leading magic bytes.
+func detectCompression(path string, peek []byte) compressionKind {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".gz":
+		return compressionGzip
+	case ".bz2":
+		return compressionBzip2
+	case ".zst":
+		return compressionZstd
+	}
+
+	switch {
+	case len(peek) >= 2 && peek[0] == 0x1f && peek[1] == 0x8b:
+		return compressionGzip
+	case len(peek) >= 3 && peek[0] == 'B' && peek[1] == 'Z' && peek[2] == 'h':
+		return compressionBzip2
+	case len(peek) >= 4 && peek[0] == 0x28 && peek[1] == 0xb5 && peek[2] == 0x2f && peek[3] == 0xfd:
+		return compressionZstd
+	}
+
+	return compressionNone
+}
+
+// decompressingReader wraps r with the appropriate decoder for kind. The
+// returned closer (if non-nil) must be called once the caller is done
+// reading.
+func decompressingReader(r io.Reader, kind compressionKind) (io.Reader, func() error, error) {
+	switch kind {
+	case compressionGzip:
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, nil, fmt.Errorf("open gzip stream: %w", err)
+		}
+		return gz, gz.Close, nil
+	case compressionBzip2:
+		return bzip2.NewReader(r), func() error { return nil }, nil
+	case compressionZstd:
+		zr, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, nil, fmt.Errorf("open zstd stream: %w", err)
+		}
+		return zr, func() error { zr.Close(); return nil }, nil
+	default:
+		return r, func() error { return nil }, nil
+	}
+}
@@ -0,0 +1,32 @@
+package onebrc
+
+import "testing"
+
+func FuzzParseTemperature(f *testing.F) {
+	seeds := []string{"5.0", "-5.0", "27.4", "0.0", "-0.0", "99.9", "", "abc", "5.", ".5", "-"}
+	for _, s := range seeds {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		ParseTemperature([]byte(s)) // must not panic for any input
+	})
+}
+
+func FuzzProcessChunk(f *testing.F) {
+	seeds := []string{
+		"Abha;5.0\nBerlin;12.3\n",
+		"",
+		"NoSemicolon\n",
+		"Station;\n",
+		";5.0\n",
+		"Station;5.0",
+		"\n\n\n",
+	}
+	for _, s := range seeds {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		data := []byte(s)
+		ProcessChunk(data, 0, len(data)) // must not panic for any input
+	})
+}
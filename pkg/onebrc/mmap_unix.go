@@ -0,0 +1,23 @@
+//go:build unix
+
+package onebrc
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// mmapFile maps the first size bytes of file into memory read-only.
+func mmapFile(file *os.File, size int64) ([]byte, error) {
+	data, err := syscall.Mmap(int(file.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, fmt.Errorf("failed to mmap file: %w", err)
+	}
+	return data, nil
+}
+
+// munmapFile unmaps a slice previously returned by mmapFile.
+func munmapFile(data []byte) error {
+	return syscall.Munmap(data)
+}
@@ -0,0 +1,42 @@
+//go:build windows
+
+package onebrc
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// mmapFile maps the first size bytes of file into memory read-only using
+// CreateFileMapping/MapViewOfFile, since syscall.Mmap is Unix-only.
+func mmapFile(file *os.File, size int64) ([]byte, error) {
+	h, err := windows.CreateFileMapping(windows.Handle(file.Fd()), nil, windows.PAGE_READONLY, uint32(size>>32), uint32(size), nil)
+	if err != nil {
+		return nil, fmt.Errorf("CreateFileMapping: %w", err)
+	}
+	defer windows.CloseHandle(h)
+
+	addr, err := windows.MapViewOfFile(h, windows.FILE_MAP_READ, 0, 0, uintptr(size))
+	if err != nil {
+		return nil, fmt.Errorf("MapViewOfFile: %w", err)
+	}
+
+	var data []byte
+	sh := (*reflect.SliceHeader)(unsafe.Pointer(&data))
+	sh.Data = addr
+	sh.Len = int(size)
+	sh.Cap = int(size)
+	return data, nil
+}
+
+// munmapFile unmaps a slice previously returned by mmapFile.
+func munmapFile(data []byte) error {
+	if len(data) == 0 {
+		return nil
+	}
+	return windows.UnmapViewOfFile(uintptr(unsafe.Pointer(&data[0])))
+}
@@ -0,0 +1,270 @@
+// Package onebrc implements the aggregation engine behind the 1brc-go CLI:
+// parsing fixed-point temperatures out of a "station;temp" measurements
+// file and computing per-station min/mean/max/count. It is split out of
+// package main so other Go programs can call into the same optimized
+// aggregator directly, without shelling out to the binary.
+package onebrc
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/TimAnthonyAlexander/1brc-go/internal/statsmap"
+)
+
+// Stats holds one station's aggregate temperature statistics. Temperatures
+// are fixed-point integers (value * 10), matching the convention used
+// throughout the parser.
+type Stats struct {
+	Min   int32
+	Max   int32
+	Sum   int64
+	Count int32
+}
+
+// Table accumulates per-station Stats, keyed by station name. It is the
+// allocation-friendly, mergeable counterpart to the internal statsmap.Table
+// used on the hot aggregation path: conversions between the two happen once
+// per chunk rather than once per row.
+type Table map[string]Stats
+
+// Merge folds other into t, combining stats for any station present in
+// both.
+func (t Table) Merge(other Table) {
+	for name, o := range other {
+		s, ok := t[name]
+		if !ok {
+			t[name] = o
+			continue
+		}
+		if o.Min < s.Min {
+			s.Min = o.Min
+		}
+		if o.Max > s.Max {
+			s.Max = o.Max
+		}
+		s.Sum += o.Sum
+		s.Count += o.Count
+		t[name] = s
+	}
+}
+
+// addResults folds the results of a statsmap.Table (as produced by
+// processChunk) into t by station name.
+func (t Table) addResults(results []statsmap.Result) {
+	for _, r := range results {
+		s, ok := t[r.Name]
+		if !ok {
+			t[r.Name] = Stats{Min: r.Min, Max: r.Max, Sum: r.Sum, Count: r.Count}
+			continue
+		}
+		if r.Min < s.Min {
+			s.Min = r.Min
+		}
+		if r.Max > s.Max {
+			s.Max = r.Max
+		}
+		s.Sum += r.Sum
+		s.Count += r.Count
+		t[r.Name] = s
+	}
+}
+
+// Options controls how AggregateFile reads and parallelizes a measurements
+// file.
+type Options struct {
+	// Workers is the number of worker goroutines used for aggregation. A
+	// value less than 1 is treated as 1.
+	Workers int
+	// ChunkSize is the target size in bytes of each work chunk fed to
+	// workers on the mmap fast path. A value less than 1 uses
+	// DefaultChunkSize.
+	ChunkSize int
+}
+
+// AggregateFile reads and aggregates the measurements file at path,
+// transparently handling gzip/bzip2/zstd-compressed input and falling back
+// to a portable read path when mmap isn't available.
+func AggregateFile(path string, opts Options) (Table, error) {
+	workers := opts.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	chunkSize := opts.ChunkSize
+	if chunkSize < 1 {
+		chunkSize = DefaultChunkSize
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	fi, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat file: %w", err)
+	}
+	fileSize := fi.Size()
+	if fileSize == 0 {
+		return Table{}, nil
+	}
+
+	peek := make([]byte, 4)
+	n, _ := file.ReadAt(peek, 0)
+	if kind := detectCompression(path, peek[:n]); kind != compressionNone {
+		return aggregateCompressed(file, kind, workers)
+	}
+	return aggregateMmap(file, fileSize, workers, chunkSize)
+}
+
+// aggregateMmap runs the mmap fast path: the file is mapped once and fed to
+// scheduleChunks. If the file is too large for this platform's address
+// space, or mmap fails for any other reason, it falls back to aggregatePread.
+func aggregateMmap(file *os.File, fileSize int64, workerCount, chunkSize int) (Table, error) {
+	if fileSize > int64(^uint(0)>>1) {
+		return aggregatePread(file, fileSize, workerCount)
+	}
+
+	data, err := mmapFile(file, fileSize)
+	if err != nil {
+		return aggregatePread(file, fileSize, workerCount)
+	}
+	defer munmapFile(data)
+
+	global := scheduleChunks(data, chunkSize, workerCount)
+
+	out := make(Table, global.Len())
+	out.addResults(global.Results())
+	return out, nil
+}
+
+// ParseTemperature parses a fixed-point temperature (value * 10) from b. It
+// is a slice-based wrapper around the index-based parser used on the mmap
+// hot path and allocates nothing.
+func ParseTemperature(b []byte) (int32, bool) {
+	return parseTemperatureFromBytes(b, 0, len(b))
+}
+
+// parseTemperatureFromBytes parses a temperature value directly from a byte array
+// within the given range [start, end). Returns fixed-point integer (temperature * 10).
+// This avoids slice allocation by working with indices.
+func parseTemperatureFromBytes(data []byte, start, end int) (int32, bool) {
+	if start >= end {
+		return 0, false
+	}
+
+	sign := int32(1)
+	i := start
+	if data[i] == '-' {
+		sign = -1
+		i++
+	}
+
+	// integral part
+	intPart := int32(0)
+	for ; i < end; i++ {
+		c := data[i]
+		if c == '.' {
+			i++
+			break
+		}
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+		intPart = intPart*10 + int32(c-'0')
+	}
+
+	// optional single decimal digit
+	fracPart := int32(0)
+	if i < end {
+		c := data[i]
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+		fracPart = int32(c - '0')
+	}
+
+	// return fixed-point integer: (intPart * 10 + fracPart) * sign
+	return sign * (intPart*10 + fracPart), true
+}
+
+// ProcessChunk walks over data[start:end) and returns the aggregate Stats
+// for each station found. It is the allocating, standalone counterpart to
+// the internal processChunk used by AggregateFile, useful for callers that
+// want to drive the parser over their own chunks (for example to fan work
+// out across machines) without going through AggregateFile.
+func ProcessChunk(data []byte, start, end int) Table {
+	st := processChunk(data, start, end)
+	out := make(Table, st.Len())
+	out.addResults(st.Results())
+	return out
+}
+
+// abs32 returns the absolute value of an int32
+func abs32(x int32) int32 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// processChunk walks over data[start:end) and returns local aggregates,
+// keyed directly against data via statsmap so no station name is allocated
+// on the hot path.
+func processChunk(data []byte, start, end int) *statsmap.Table {
+	// ensure we start at a line boundary (caller guarantees start==0 for the
+	// very first chunk)
+	if start != 0 {
+		for start < end && data[start-1] != '\n' {
+			start++
+		}
+	}
+
+	local := statsmap.New(data)
+
+	i := start
+	for i < end {
+		// find newline separating the current line
+		j := bytes.IndexByte(data[i:end], '\n')
+		if j == -1 {
+			// no complete line in the remaining slice – break; the next chunk
+			// (or EOF if last) will handle it
+			break
+		}
+		lineStart := i
+		line := data[lineStart : lineStart+j]
+		i = lineStart + j + 1 // move past "line + \n"
+
+		if len(line) == 0 {
+			continue // skip empty lines
+		}
+
+		// Scan backwards from the end to find the semicolon
+		// This is faster than bytes.IndexByte which scans forward
+		semicolonIdx := -1
+		for i := len(line) - 1; i >= 0; i-- {
+			if line[i] == ';' {
+				semicolonIdx = i
+				break
+			}
+		}
+
+		if semicolonIdx <= 0 || semicolonIdx >= len(line)-1 {
+			continue // malformed – ignore
+		}
+
+		// Parse temperature directly from the tail (no slice allocation)
+		temp, ok := parseTemperatureFromBytes(line, semicolonIdx+1, len(line))
+		if !ok {
+			continue // skip invalid values
+		}
+
+		// Station name stays as an offset into data; statsmap compares keys
+		// by bytes, so no allocation happens here.
+		local.Add(lineStart, lineStart+semicolonIdx, temp)
+	}
+
+	return local
+}
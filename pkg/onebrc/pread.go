@@ -0,0 +1,156 @@
+package onebrc
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// aggregatePread is the portable fallback used when mmap is unavailable
+// (32-bit targets with a file too large for the address space) or fails for
+// any other reason.
+func aggregatePread(file *os.File, fileSize int64, workerCount int) (Table, error) {
+	return aggregatePreadWorkers(file, fileSize, workerCount)
+}
+
+// newlineScanBlock is the read size used while hunting for the newline a
+// worker boundary should land on.
+const newlineScanBlock = 64 * 1024
+
+// preadBufPool recycles the per-worker read buffers across calls so repeat
+// aggregations (e.g. benchmarks) don't churn one large allocation per
+// worker per call.
+var preadBufPool = sync.Pool{
+	New: func() any {
+		buf := make([]byte, 0, DefaultChunkSize)
+		return &buf
+	},
+}
+
+// alignToNextNewline returns the offset of the first byte after the next
+// '\n' at or after raw, reading forward in small blocks via ReadAt. It
+// mirrors what splitChunks does against a mmapped slice (pkg/onebrc/
+// scheduler.go), just driven by reads instead of pointer arithmetic, so a
+// worker's range here never splits a line.
+func alignToNextNewline(file *os.File, raw, fileSize int64) (int64, error) {
+	if raw >= fileSize {
+		return fileSize, nil
+	}
+
+	block := make([]byte, newlineScanBlock)
+	pos := raw
+	for pos < fileSize {
+		n, err := file.ReadAt(block, pos)
+		if err != nil && err != io.EOF {
+			return 0, fmt.Errorf("scan for newline at %d: %w", pos, err)
+		}
+		if idx := indexByte(block[:n], '\n'); idx >= 0 {
+			return pos + int64(idx) + 1, nil
+		}
+		pos += int64(n)
+		if n == 0 {
+			break
+		}
+	}
+	return fileSize, nil
+}
+
+func indexByte(b []byte, c byte) int {
+	for i, v := range b {
+		if v == c {
+			return i
+		}
+	}
+	return -1
+}
+
+// preadChunkBoundaries splits [0,fileSize) into workerCount ranges, each
+// realigned so it ends exactly on a newline the way splitChunks does for
+// the mmap path, chaining each worker's start to the previous worker's
+// aligned end so no byte is read twice and no row is dropped.
+func preadChunkBoundaries(file *os.File, fileSize int64, workerCount int) ([]int64, error) {
+	chunkSize := fileSize / int64(workerCount)
+
+	boundaries := make([]int64, workerCount+1)
+	boundaries[workerCount] = fileSize
+	for i := 1; i < workerCount; i++ {
+		raw := int64(i) * chunkSize
+		if raw < boundaries[i-1] {
+			raw = boundaries[i-1]
+		}
+		end, err := alignToNextNewline(file, raw, fileSize)
+		if err != nil {
+			return nil, err
+		}
+		boundaries[i] = end
+	}
+	return boundaries, nil
+}
+
+// aggregatePreadWorkers assigns [start,end) byte ranges to workerCount
+// workers, each realigned to a newline boundary, each of which reads its
+// range with ReadAt into a pooled buffer and runs the existing
+// processChunk logic.
+func aggregatePreadWorkers(file *os.File, fileSize int64, workerCount int) (Table, error) {
+	if int64(workerCount) > fileSize {
+		workerCount = int(fileSize)
+	}
+	if workerCount < 1 {
+		workerCount = 1
+	}
+
+	boundaries, err := preadChunkBoundaries(file, fileSize, workerCount)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		acc     = make(Table)
+		errOnce sync.Once
+		firstEr error
+	)
+
+	for i := 0; i < workerCount; i++ {
+		start, end := boundaries[i], boundaries[i+1]
+		if start >= end {
+			continue
+		}
+
+		wg.Add(1)
+		go func(start, end int64) {
+			defer wg.Done()
+
+			bufPtr := preadBufPool.Get().(*[]byte)
+			defer preadBufPool.Put(bufPtr)
+
+			need := int(end - start)
+			if cap(*bufPtr) < need {
+				*bufPtr = make([]byte, need)
+			} else {
+				*bufPtr = (*bufPtr)[:need]
+			}
+			buf := *bufPtr
+
+			if _, err := file.ReadAt(buf, start); err != nil && err != io.EOF {
+				errOnce.Do(func() { firstEr = fmt.Errorf("read range [%d,%d): %w", start, end, err) })
+				return
+			}
+
+			table := processChunk(buf, 0, len(buf))
+
+			mu.Lock()
+			acc.addResults(table.Results())
+			mu.Unlock()
+		}(start, end)
+	}
+
+	wg.Wait()
+
+	if firstEr != nil {
+		return nil, firstEr
+	}
+	return acc, nil
+}
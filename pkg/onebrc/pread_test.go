@@ -0,0 +1,71 @@
+package onebrc
+
+import (
+	"os"
+	"testing"
+)
+
+func TestAggregatePread(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "measurements-*.txt")
+	if err != nil {
+		t.Fatalf("CreateTemp: %v", err)
+	}
+	defer f.Close()
+
+	content := "Abha;5.0\nAbha;27.4\nBerlin;12.3\nBerlin;-1.0\n"
+	if _, err := f.WriteString(content); err != nil {
+		t.Fatalf("WriteString: %v", err)
+	}
+
+	got, err := aggregatePreadWorkers(f, int64(len(content)), 1)
+	if err != nil {
+		t.Fatalf("aggregatePreadWorkers: %v", err)
+	}
+
+	abha, ok := got["Abha"]
+	if !ok || abha.Min != 50 || abha.Max != 274 || abha.Count != 2 {
+		t.Fatalf("Abha = %+v, ok=%v, want min=50 max=274 count=2", abha, ok)
+	}
+
+	berlin, ok := got["Berlin"]
+	if !ok || berlin.Min != -10 || berlin.Max != 123 || berlin.Count != 2 {
+		t.Fatalf("Berlin = %+v, ok=%v, want min=-10 max=123 count=2", berlin, ok)
+	}
+}
+
+func TestAggregatePreadMultipleWorkers(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "measurements-*.txt")
+	if err != nil {
+		t.Fatalf("CreateTemp: %v", err)
+	}
+	defer f.Close()
+
+	const lines = 5000
+	var size int64
+	for i := 0; i < lines; i++ {
+		n, err := f.WriteString("Fixed;10.0\n")
+		if err != nil {
+			t.Fatalf("WriteString: %v", err)
+		}
+		size += int64(n)
+	}
+
+	got, err := aggregatePreadWorkers(f, size, 8)
+	if err != nil {
+		t.Fatalf("aggregatePreadWorkers: %v", err)
+	}
+
+	fixed, ok := got["Fixed"]
+	if !ok {
+		t.Fatalf("missing Fixed entry")
+	}
+	if fixed.Min != 100 || fixed.Max != 100 {
+		t.Fatalf("Fixed = %+v, want min=max=100", fixed)
+	}
+	// Worker boundaries are realigned to the next newline (mirroring
+	// splitChunks for the mmap path), so every row is counted exactly
+	// once regardless of how many workers split the file.
+	if fixed.Count != lines {
+		t.Fatalf("Fixed.Count = %d, want exactly %d", fixed.Count, lines)
+	}
+}
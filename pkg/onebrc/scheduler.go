@@ -0,0 +1,84 @@
+package onebrc
+
+import (
+	"sync"
+
+	"github.com/TimAnthonyAlexander/1brc-go/internal/statsmap"
+)
+
+// DefaultChunkSize is the target size of each work chunk fed to the
+// scheduler below, within the 4-16 MiB sweet spot. Options.ChunkSize falls
+// back to it when unset.
+const DefaultChunkSize = 8 * 1024 * 1024
+
+// chunkRange is a [start,end) byte range of a mmapped file, realigned so it
+// never splits a line.
+type chunkRange struct {
+	start, end int
+}
+
+// splitChunks partitions data into chunks of approximately chunkSize bytes,
+// each one realigned to end exactly on a newline.
+func splitChunks(data []byte, chunkSize int) []chunkRange {
+	if chunkSize < 1 {
+		chunkSize = DefaultChunkSize
+	}
+
+	var chunks []chunkRange
+	start := 0
+	for start < len(data) {
+		end := start + chunkSize
+		if end >= len(data) {
+			end = len(data)
+		} else {
+			for end < len(data) && data[end-1] != '\n' {
+				end++
+			}
+		}
+		chunks = append(chunks, chunkRange{start, end})
+		start = end
+	}
+	return chunks
+}
+
+// scheduleChunks splits data into many small chunks and feeds them through a
+// buffered channel to a fixed pool of workerCount workers, rather than
+// slicing the file into workerCount equal ranges up front. Each worker pulls
+// chunks until the channel is drained, merging them into a worker-local
+// table that is flushed into the shared result once the worker is done.
+// This keeps every worker busy until the file is exhausted even when
+// station-name distributions or NUMA effects make some chunks slower than
+// others, which a static equal partition can't adapt to.
+func scheduleChunks(data []byte, chunkSize, workerCount int) *statsmap.Table {
+	if workerCount < 1 {
+		workerCount = 1
+	}
+
+	chunks := splitChunks(data, chunkSize)
+	work := make(chan chunkRange, len(chunks))
+	for _, c := range chunks {
+		work <- c
+	}
+	close(work)
+
+	global := statsmap.New(data)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for i := 0; i < workerCount; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			local := statsmap.New(data)
+			for c := range work {
+				local.Merge(processChunk(data, c.start, c.end))
+			}
+			mu.Lock()
+			global.Merge(local)
+			mu.Unlock()
+		}()
+	}
+
+	wg.Wait()
+	return global
+}
@@ -0,0 +1,45 @@
+package onebrc
+
+import "testing"
+
+func TestSplitChunksNeverSplitsALine(t *testing.T) {
+	data := []byte("Abha;5.0\nBerlin;12.3\nZurich;0.0\nTokyo;9.9\n")
+
+	chunks := splitChunks(data, 10)
+	if len(chunks) == 0 {
+		t.Fatalf("expected at least one chunk")
+	}
+
+	if chunks[0].start != 0 {
+		t.Fatalf("first chunk should start at 0, got %d", chunks[0].start)
+	}
+	if chunks[len(chunks)-1].end != len(data) {
+		t.Fatalf("last chunk should end at %d, got %d", len(data), chunks[len(chunks)-1].end)
+	}
+
+	for i, c := range chunks {
+		if c.end < len(data) && data[c.end-1] != '\n' {
+			t.Fatalf("chunk %d ends mid-line: %q", i, data[c.start:c.end])
+		}
+		if i > 0 && c.start != chunks[i-1].end {
+			t.Fatalf("chunk %d does not start where chunk %d ended", i, i-1)
+		}
+	}
+}
+
+func TestScheduleChunksMatchesSingleChunk(t *testing.T) {
+	data := []byte("Abha;5.0\nAbha;27.4\nBerlin;12.3\nBerlin;-1.0\nZurich;0.0\n")
+
+	got := scheduleChunks(data, 12, 4)
+	byName := make(map[string]int32, got.Len())
+	for _, r := range got.Results() {
+		byName[r.Name] = r.Count
+	}
+
+	want := map[string]int32{"Abha": 2, "Berlin": 2, "Zurich": 1}
+	for name, count := range want {
+		if byName[name] != count {
+			t.Fatalf("%s count = %d, want %d", name, byName[name], count)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package onebrc
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// streamBlockSize is the size of each buffer read from a compressed stream
+// before it is handed to a worker. Compressed inputs can't be mmapped
+// randomly, so this is the unit of parallelism for that path instead.
+const streamBlockSize = 8 * 1024 * 1024 // 8 MiB
+
+var streamBufPool = sync.Pool{
+	New: func() any {
+		b := make([]byte, streamBlockSize+4096) // headroom for a trailing partial line
+		return &b
+	},
+}
+
+// aggregateCompressed decodes a compressed measurements file and runs it
+// through the streaming AggregateReader path, since compressed data can't be
+// mmapped.
+func aggregateCompressed(file *os.File, kind compressionKind, workerCount int) (Table, error) {
+	reader, closeReader, err := decompressingReader(file, kind)
+	if err != nil {
+		return nil, err
+	}
+	defer closeReader()
+
+	return AggregateReader(reader, workerCount)
+}
+
+// AggregateReader reads measurements from r, splitting it into fixed-size
+// blocks that are processed by workers using the same processChunk logic as
+// the mmap fast path, and returns the merged per-station results. It is the
+// shared aggregation core for any input that can't be mmapped, such as
+// compressed streams.
+func AggregateReader(r io.Reader, workers int) (Table, error) {
+	if workers < 1 {
+		workers = 1
+	}
+
+	br := bufio.NewReaderSize(r, streamBlockSize)
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		acc     = make(Table)
+		workCh  = make(chan []byte, workers)
+		errOnce sync.Once
+		firstEr error
+	)
+
+	setErr := func(err error) {
+		errOnce.Do(func() { firstEr = err })
+	}
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for block := range workCh {
+				table := processChunk(block, 0, len(block))
+				mu.Lock()
+				acc.addResults(table.Results())
+				mu.Unlock()
+			}
+		}()
+	}
+
+	var carry []byte
+	for {
+		bufPtr := streamBufPool.Get().(*[]byte)
+		buf := (*bufPtr)[:cap(*bufPtr)]
+
+		n := copy(buf, carry)
+		read, err := io.ReadFull(br, buf[n:])
+		n += read
+
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			streamBufPool.Put(bufPtr)
+			setErr(fmt.Errorf("read measurements: %w", err))
+			break
+		}
+
+		eof := err == io.EOF || err == io.ErrUnexpectedEOF
+		chunk := buf[:n]
+
+		// cut stays 0 when the block holds no newline: at EOF the whole
+		// block is then the trailing partial line, otherwise the block is
+		// full and the line is too long (checked below).
+		cut := 0
+		if idx := bytes.LastIndexByte(chunk, '\n'); idx >= 0 {
+			cut = idx + 1
+		}
+
+		if cut == 0 && !eof && n == len(buf) {
+			// The carried-forward data already fills a whole buffer and
+			// still contains no newline, so growing it again would just
+			// read zero more bytes and spin forever instead of making
+			// progress – bail out instead.
+			streamBufPool.Put(bufPtr)
+			setErr(fmt.Errorf("read measurements: line exceeds max buffer size (%d bytes)", len(buf)))
+			break
+		}
+
+		next := append([]byte(nil), chunk[cut:]...)
+
+		block := append([]byte(nil), chunk[:cut]...)
+		streamBufPool.Put(bufPtr)
+
+		if len(block) > 0 {
+			workCh <- block
+		}
+		carry = next
+
+		if eof {
+			if len(carry) > 0 {
+				workCh <- append(carry, '\n')
+			}
+			break
+		}
+	}
+	close(workCh)
+	wg.Wait()
+
+	if firstEr != nil {
+		return nil, firstEr
+	}
+	return acc, nil
+}
@@ -0,0 +1,73 @@
+package onebrc
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestDetectCompression(t *testing.T) {
+	cases := []struct {
+		name string
+		path string
+		peek []byte
+		want compressionKind
+	}{
+		{"gzip extension", "measurements.txt.gz", nil, compressionGzip},
+		{"bzip2 extension", "measurements.txt.bz2", nil, compressionBzip2},
+		{"zstd extension", "measurements.txt.zst", nil, compressionZstd},
+		{"plain extension", "measurements.txt", nil, compressionNone},
+		{"gzip magic", "measurements.dat", []byte{0x1f, 0x8b, 0x08, 0x00}, compressionGzip},
+		{"bzip2 magic", "measurements.dat", []byte("BZh9"), compressionBzip2},
+		{"zstd magic", "measurements.dat", []byte{0x28, 0xb5, 0x2f, 0xfd}, compressionZstd},
+		{"no signal", "measurements.dat", []byte("12.3\n"), compressionNone},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := detectCompression(tc.path, tc.peek); got != tc.want {
+				t.Errorf("detectCompression(%q, %v) = %v, want %v", tc.path, tc.peek, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestAggregateReaderMatchesProcessChunk(t *testing.T) {
+	data := []byte("Abha;5.0\nAbha;27.4\nBerlin;12.3\nBerlin;-1.0\n")
+
+	got, err := AggregateReader(bytes.NewReader(data), 2)
+	if err != nil {
+		t.Fatalf("AggregateReader: %v", err)
+	}
+
+	abha, ok := got["Abha"]
+	if !ok || abha.Min != 50 || abha.Max != 274 || abha.Count != 2 {
+		t.Fatalf("Abha = %+v, ok=%v, want min=50 max=274 count=2", abha, ok)
+	}
+
+	berlin, ok := got["Berlin"]
+	if !ok || berlin.Min != -10 || berlin.Max != 123 || berlin.Count != 2 {
+		t.Fatalf("Berlin = %+v, ok=%v, want min=-10 max=123 count=2", berlin, ok)
+	}
+}
+
+func TestAggregateReaderLineTooLong(t *testing.T) {
+	// A run of bytes with no newline larger than a read buffer must error
+	// out rather than spin forever carrying the same full buffer forward.
+	data := bytes.Repeat([]byte("x"), streamBlockSize+4096+1)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := AggregateReader(bytes.NewReader(data), 2)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatal("expected an error for a line exceeding the max buffer size, got nil")
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("AggregateReader hung instead of returning an error")
+	}
+}